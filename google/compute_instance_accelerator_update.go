@@ -0,0 +1,42 @@
+package google
+
+import (
+	"fmt"
+
+	computeBeta "google.golang.org/api/compute/v0.beta"
+)
+
+// updateGuestAccelerators applies a changed set of guest_accelerator blocks
+// to a running instance. Accelerators can only be changed while the
+// instance is stopped, so this stops the instance, patches the machine
+// resources, and starts it back up. Callers are expected to gate this on an
+// allow_stopping_for_update argument, since restarting the instance is
+// disruptive.
+func updateGuestAccelerators(config *Config, project, zone, instance string, accelerators []*computeBeta.AcceleratorConfig) error {
+	instancesService := config.clientComputeBeta.Instances
+
+	stopOp, err := instancesService.Stop(project, zone, instance).Do()
+	if err != nil {
+		return fmt.Errorf("Error stopping instance %q to update guest accelerators: %s", instance, err)
+	}
+	if err := computeSharedOperationWait(config.clientCompute, stopOp, project, "stopping instance"); err != nil {
+		return err
+	}
+
+	req := &computeBeta.InstancesSetMachineResourcesRequest{
+		GuestAccelerators: accelerators,
+	}
+	resourcesOp, err := instancesService.SetMachineResources(project, zone, instance, req).Do()
+	if err != nil {
+		return fmt.Errorf("Error updating guest accelerators on instance %q: %s", instance, err)
+	}
+	if err := computeSharedOperationWait(config.clientCompute, resourcesOp, project, "updating guest accelerators"); err != nil {
+		return err
+	}
+
+	startOp, err := instancesService.Start(project, zone, instance).Do()
+	if err != nil {
+		return fmt.Errorf("Error restarting instance %q after updating guest accelerators: %s", instance, err)
+	}
+	return computeSharedOperationWait(config.clientCompute, startOp, project, "starting instance")
+}
@@ -2,10 +2,10 @@ package google
 
 import (
 	"fmt"
-	"regexp"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	computeBeta "google.golang.org/api/compute/v0.beta"
+	"google.golang.org/api/googleapi"
 )
 
 func expandAliasIpRanges(ranges []interface{}) []*computeBeta.AliasIpRange {
@@ -36,6 +36,8 @@ func flattenScheduling(scheduling *computeBeta.Scheduling) []map[string]interfac
 	schedulingMap := map[string]interface{}{
 		"on_host_maintenance": scheduling.OnHostMaintenance,
 		"preemptible":         scheduling.Preemptible,
+		"node_affinities":     flattenSchedulingNodeAffinities(scheduling.NodeAffinities),
+		"min_node_cpus":       scheduling.MinNodeCpus,
 	}
 	if scheduling.AutomaticRestart != nil {
 		schedulingMap["automatic_restart"] = *scheduling.AutomaticRestart
@@ -44,41 +46,94 @@ func flattenScheduling(scheduling *computeBeta.Scheduling) []map[string]interfac
 	return result
 }
 
-func getProjectAndRegionFromSubnetworkLink(subnetwork string) (string, string) {
-	r := regexp.MustCompile(SubnetworkLinkRegex)
-	if !r.MatchString(subnetwork) {
-		return "", ""
+func flattenSchedulingNodeAffinities(affinities []*computeBeta.SchedulingNodeAffinity) []map[string]interface{} {
+	flattened := make([]map[string]interface{}, 0, len(affinities))
+	for _, affinity := range affinities {
+		flattened = append(flattened, map[string]interface{}{
+			"key":      affinity.Key,
+			"operator": affinity.Operator,
+			"values":   convertStringArrToInterface(affinity.Values),
+		})
+	}
+	return flattened
+}
+
+func expandScheduling(v interface{}) (*computeBeta.Scheduling, error) {
+	if v == nil {
+		return &computeBeta.Scheduling{}, nil
+	}
+	ls := v.([]interface{})
+	if len(ls) == 0 {
+		return &computeBeta.Scheduling{}, nil
+	}
+
+	data := ls[0].(map[string]interface{})
+	scheduling := &computeBeta.Scheduling{
+		AutomaticRestart:  googleapi.Bool(data["automatic_restart"].(bool)),
+		Preemptible:       data["preemptible"].(bool),
+		OnHostMaintenance: data["on_host_maintenance"].(string),
+		NodeAffinities:    expandSchedulingNodeAffinities(data["node_affinities"].(*schema.Set).List()),
+		MinNodeCpus:       int64(data["min_node_cpus"].(int)),
+		ForceSendFields:   []string{"AutomaticRestart", "Preemptible", "MinNodeCpus", "NodeAffinities"},
 	}
 
-	matches := r.FindStringSubmatch(subnetwork)
-	return matches[1], matches[2]
+	return scheduling, nil
 }
 
-func flattenAccessConfigs(accessConfigs []*computeBeta.AccessConfig) ([]map[string]interface{}, string) {
-	flattened := make([]map[string]interface{}, len(accessConfigs))
+func expandSchedulingNodeAffinities(configs []interface{}) []*computeBeta.SchedulingNodeAffinity {
+	affinities := make([]*computeBeta.SchedulingNodeAffinity, 0, len(configs))
+	for _, raw := range configs {
+		data := raw.(map[string]interface{})
+		affinities = append(affinities, &computeBeta.SchedulingNodeAffinity{
+			Key:      data["key"].(string),
+			Operator: data["operator"].(string),
+			Values:   convertStringSet(data["values"].(*schema.Set)),
+		})
+	}
+	return affinities
+}
+
+func flattenAccessConfigs(accessConfigs []*computeBeta.AccessConfig) ([]map[string]interface{}, []map[string]interface{}, string) {
+	flattened := make([]map[string]interface{}, 0, len(accessConfigs))
+	ipv6Flattened := make([]map[string]interface{}, 0, len(accessConfigs))
 	natIP := ""
-	for i, ac := range accessConfigs {
-		flattened[i] = map[string]interface{}{
-			"nat_ip":          ac.NatIP,
-			"assigned_nat_ip": ac.NatIP,
+	for _, ac := range accessConfigs {
+		if ac.Type == "DIRECT_IPV6" {
+			ipv6Flattened = append(ipv6Flattened, map[string]interface{}{
+				"network_tier":           ac.NetworkTier,
+				"public_ptr_domain_name": ac.PublicPtrDomainName,
+			})
+			continue
+		}
+
+		ac4 := map[string]interface{}{
+			"nat_ip":                 ac.NatIP,
+			"assigned_nat_ip":        ac.NatIP,
+			"network_tier":           ac.NetworkTier,
+			"public_ptr_domain_name": ac.PublicPtrDomainName,
 		}
+		flattened = append(flattened, ac4)
 		if natIP == "" {
 			natIP = ac.NatIP
 		}
 	}
-	return flattened, natIP
+	return flattened, ipv6Flattened, natIP
 }
 
-func flattenNetworkInterfaces(networkInterfaces []*computeBeta.NetworkInterface) ([]map[string]interface{}, string, string, string) {
+func flattenNetworkInterfaces(d *schema.ResourceData, config *Config, networkInterfaces []*computeBeta.NetworkInterface) ([]map[string]interface{}, string, string, string, error) {
 	flattened := make([]map[string]interface{}, len(networkInterfaces))
 	var region, internalIP, externalIP string
 
 	for i, iface := range networkInterfaces {
-		var ac []map[string]interface{}
-		ac, externalIP = flattenAccessConfigs(iface.AccessConfigs)
+		var ac, ac6 []map[string]interface{}
+		ac, ac6, externalIP = flattenAccessConfigs(iface.AccessConfigs)
 
-		var project string
-		project, region = getProjectAndRegionFromSubnetworkLink(iface.Subnetwork)
+		subnetwork, err := ParseSubnetworkFieldValue(iface.Subnetwork, d, config)
+		if err != nil {
+			return nil, "", "", "", err
+		}
+		project := subnetwork.Project
+		region = subnetwork.Region
 
 		flattened[i] = map[string]interface{}{
 			"address":            iface.NetworkIP,
@@ -87,7 +142,12 @@ func flattenNetworkInterfaces(networkInterfaces []*computeBeta.NetworkInterface)
 			"subnetwork":         iface.Subnetwork,
 			"subnetwork_project": project,
 			"access_config":      ac,
+			"ipv6_access_config": ac6,
 			"alias_ip_range":     flattenAliasIpRange(iface.AliasIpRanges),
+			// fingerprint isn't user-settable, but we need to carry it on the
+			// read so a subsequent in-place update of alias_ip_range can send
+			// it back to the API to avoid a 412 stale-fingerprint error.
+			"fingerprint": iface.Fingerprint,
 		}
 		// Instance template interfaces never have names, so they're absent
 		// in the instance template network_interface schema. We want to use the
@@ -100,31 +160,36 @@ func flattenNetworkInterfaces(networkInterfaces []*computeBeta.NetworkInterface)
 			internalIP = iface.NetworkIP
 		}
 	}
-	return flattened, region, internalIP, externalIP
+	return flattened, region, internalIP, externalIP, nil
 }
 
-func expandAccessConfigs(configs []interface{}) []*computeBeta.AccessConfig {
-	acs := make([]*computeBeta.AccessConfig, len(configs))
-	for i, raw := range configs {
+func expandAccessConfigs(configs, ipv6Configs []interface{}) []*computeBeta.AccessConfig {
+	acs := make([]*computeBeta.AccessConfig, 0, len(configs)+len(ipv6Configs))
+	for _, raw := range configs {
+		data := raw.(map[string]interface{})
+		ac := &computeBeta.AccessConfig{
+			Type:                "ONE_TO_ONE_NAT",
+			NatIP:               data["nat_ip"].(string),
+			NetworkTier:         data["network_tier"].(string),
+			PublicPtrDomainName: data["public_ptr_domain_name"].(string),
+		}
+		ac.SetPublicPtr = ac.PublicPtrDomainName != ""
+		acs = append(acs, ac)
+	}
+	for _, raw := range ipv6Configs {
 		data := raw.(map[string]interface{})
-		acs[i] = &computeBeta.AccessConfig{
-			Type:  "ONE_TO_ONE_NAT",
-			NatIP: data["nat_ip"].(string),
+		ac := &computeBeta.AccessConfig{
+			Type:                "DIRECT_IPV6",
+			NetworkTier:         data["network_tier"].(string),
+			PublicPtrDomainName: data["public_ptr_domain_name"].(string),
 		}
+		ac.SetPublicPtr = ac.PublicPtrDomainName != ""
+		acs = append(acs, ac)
 	}
 	return acs
 }
 
 func expandNetworkInterfaces(d *schema.ResourceData, config *Config) ([]*computeBeta.NetworkInterface, error) {
-	project, err := getProject(d, config)
-	if err != nil {
-		return nil, err
-	}
-	region, err := getRegion(d, config)
-	if err != nil {
-		return nil, err
-	}
-
 	configs := d.Get("network_interface").([]interface{})
 	ifaces := make([]*computeBeta.NetworkInterface, len(configs))
 	for i, raw := range configs {
@@ -141,17 +206,19 @@ func expandNetworkInterfaces(d *schema.ResourceData, config *Config) ([]*compute
 			return nil, fmt.Errorf("cannot determine selflink for subnetwork '%s': %s", subnetwork, err)
 		}
 
-		subnetworkProject := data["subnetwork_project"].(string)
-		subnetLink, err := getSubnetworkLink(config, project, region, subnetworkProject, subnetwork)
+		sf, err := ParseSubnetworkFieldValue(subnetwork, d, config)
 		if err != nil {
 			return nil, fmt.Errorf("cannot determine selflink for subnetwork '%s': %s", subnetwork, err)
 		}
+		if subnetworkProject := data["subnetwork_project"].(string); subnetworkProject != "" {
+			sf.Project = subnetworkProject
+		}
 
 		ifaces[i] = &computeBeta.NetworkInterface{
 			NetworkIP:     data["network_ip"].(string),
 			Network:       nf.RelativeLink(),
-			Subnetwork:    subnetLink,
-			AccessConfigs: expandAccessConfigs(data["access_config"].([]interface{})),
+			Subnetwork:    sf.RelativeLink(),
+			AccessConfigs: expandAccessConfigs(data["access_config"].([]interface{}), data["ipv6_access_config"].([]interface{})),
 			AliasIpRanges: expandAliasIpRanges(data["alias_ip_range"].([]interface{})),
 		}
 
@@ -203,6 +270,18 @@ func flattenGuestAccelerators(accelerators []*computeBeta.AcceleratorConfig) []m
 	return acceleratorsSchema
 }
 
+func expandGuestAccelerators(configs []interface{}) []*computeBeta.AcceleratorConfig {
+	accelerators := make([]*computeBeta.AcceleratorConfig, 0, len(configs))
+	for _, raw := range configs {
+		data := raw.(map[string]interface{})
+		accelerators = append(accelerators, &computeBeta.AcceleratorConfig{
+			AcceleratorCount: int64(data["count"].(int)),
+			AcceleratorType:  data["type"].(string),
+		})
+	}
+	return accelerators
+}
+
 func resourceInstanceTags(d *schema.ResourceData) *computeBeta.Tags {
 	// Calculate the tags
 	var tags *computeBeta.Tags
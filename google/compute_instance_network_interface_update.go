@@ -0,0 +1,107 @@
+package google
+
+import (
+	"fmt"
+	"reflect"
+
+	computeBeta "google.golang.org/api/compute/v0.beta"
+	"google.golang.org/api/googleapi"
+)
+
+// networkInterfaceAliasIpRangeDiff is one network_interface entry whose
+// alias_ip_range changed between the old and new resource state.
+type networkInterfaceAliasIpRangeDiff struct {
+	Index  int
+	Name   string
+	Ranges []*computeBeta.AliasIpRange
+}
+
+// networkInterfaceAliasIpRangeDiffs compares the old and new network_interface
+// lists (as returned by d.GetChange("network_interface")) and returns a diff
+// entry for each interface whose alias_ip_range actually changed, so the
+// caller only has to call the update API for interfaces that need it.
+func networkInterfaceAliasIpRangeDiffs(oldIfaces, newIfaces []interface{}) ([]networkInterfaceAliasIpRangeDiff, error) {
+	var diffs []networkInterfaceAliasIpRangeDiff
+
+	for i, newRaw := range newIfaces {
+		if i >= len(oldIfaces) {
+			break
+		}
+		oldData := oldIfaces[i].(map[string]interface{})
+		newData := newRaw.(map[string]interface{})
+
+		oldRanges := expandAliasIpRanges(oldData["alias_ip_range"].([]interface{}))
+		newRanges := expandAliasIpRanges(newData["alias_ip_range"].([]interface{}))
+		if reflect.DeepEqual(oldRanges, newRanges) {
+			continue
+		}
+
+		ifaceName := oldData["name"].(string)
+		if ifaceName == "" {
+			return nil, fmt.Errorf("network_interface.%d.name is required to update alias_ip_range in place", i)
+		}
+
+		diffs = append(diffs, networkInterfaceAliasIpRangeDiff{Index: i, Name: ifaceName, Ranges: newRanges})
+	}
+
+	return diffs, nil
+}
+
+// updateNetworkInterfaceAliasIpRanges applies an in-place update of the
+// alias_ip_range block for a single network interface, using the
+// interface-level fingerprint to avoid clobbering concurrent changes. The
+// GCE API rejects the request with a 412 if the fingerprint is stale, so the
+// caller is expected to refetch the instance and retry with the new
+// fingerprint; this mirrors how other fingerprinted fields (tags, metadata)
+// are already updated elsewhere in this provider.
+func updateNetworkInterfaceAliasIpRanges(config *Config, project, zone, instance, ifaceName string, aliasIpRanges []*computeBeta.AliasIpRange, fingerprint string) error {
+	patch := &computeBeta.NetworkInterface{
+		AliasIpRanges:   aliasIpRanges,
+		Fingerprint:     fingerprint,
+		ForceSendFields: []string{"AliasIpRanges"},
+	}
+
+	op, err := config.clientComputeBeta.Instances.UpdateNetworkInterface(project, zone, instance, ifaceName, patch).Do()
+	if err != nil {
+		return err
+	}
+
+	return computeSharedOperationWait(config.clientCompute, op, project, "updating network interface")
+}
+
+// updateNetworkInterfaceAliasIpRangesWithRetry retries updateNetworkInterfaceAliasIpRanges
+// on a stale-fingerprint (412) response, refetching the current interface
+// fingerprint from the API before each retry.
+func updateNetworkInterfaceAliasIpRangesWithRetry(config *Config, project, zone, instance, ifaceName string, aliasIpRanges []*computeBeta.AliasIpRange) error {
+	const maxAttempts = 3
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		current, err := config.clientComputeBeta.Instances.Get(project, zone, instance).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading instance to refresh network interface fingerprint: %s", err)
+		}
+
+		var fingerprint string
+		for _, iface := range current.NetworkInterfaces {
+			if iface.Name == ifaceName {
+				fingerprint = iface.Fingerprint
+				break
+			}
+		}
+		if fingerprint == "" {
+			return fmt.Errorf("could not find network interface %q on instance %q to update alias_ip_range", ifaceName, instance)
+		}
+
+		err = updateNetworkInterfaceAliasIpRanges(config, project, zone, instance, ifaceName, aliasIpRanges, fingerprint)
+		if err == nil {
+			return nil
+		}
+
+		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 412 {
+			continue
+		}
+		return err
+	}
+
+	return fmt.Errorf("Error updating alias_ip_range on network interface %q: ran out of retries on stale fingerprint", ifaceName)
+}
@@ -0,0 +1,148 @@
+package google
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	computeBeta "google.golang.org/api/compute/v0.beta"
+)
+
+func TestNetworkInterfaceAliasIpRangeDiffs(t *testing.T) {
+	cases := map[string]struct {
+		old     []interface{}
+		new     []interface{}
+		want    []networkInterfaceAliasIpRangeDiff
+		wantErr bool
+	}{
+		"no change": {
+			old: []interface{}{
+				map[string]interface{}{
+					"name": "nic0",
+					"alias_ip_range": []interface{}{
+						map[string]interface{}{"ip_cidr_range": "10.0.0.0/24", "subnetwork_range_name": ""},
+					},
+				},
+			},
+			new: []interface{}{
+				map[string]interface{}{
+					"name": "nic0",
+					"alias_ip_range": []interface{}{
+						map[string]interface{}{"ip_cidr_range": "10.0.0.0/24", "subnetwork_range_name": ""},
+					},
+				},
+			},
+			want: nil,
+		},
+		"changed range": {
+			old: []interface{}{
+				map[string]interface{}{
+					"name":           "nic0",
+					"alias_ip_range": []interface{}{},
+				},
+			},
+			new: []interface{}{
+				map[string]interface{}{
+					"name": "nic0",
+					"alias_ip_range": []interface{}{
+						map[string]interface{}{"ip_cidr_range": "10.0.1.0/24", "subnetwork_range_name": "r1"},
+					},
+				},
+			},
+			want: []networkInterfaceAliasIpRangeDiff{
+				{
+					Index: 0,
+					Name:  "nic0",
+					Ranges: []*computeBeta.AliasIpRange{
+						{IpCidrRange: "10.0.1.0/24", SubnetworkRangeName: "r1"},
+					},
+				},
+			},
+		},
+		"fewer new interfaces than old stops at the shorter length": {
+			old: []interface{}{
+				map[string]interface{}{"name": "nic0", "alias_ip_range": []interface{}{}},
+				map[string]interface{}{"name": "nic1", "alias_ip_range": []interface{}{
+					map[string]interface{}{"ip_cidr_range": "10.0.2.0/24", "subnetwork_range_name": ""},
+				}},
+			},
+			new: []interface{}{
+				map[string]interface{}{"name": "nic0", "alias_ip_range": []interface{}{}},
+			},
+			want: nil,
+		},
+		"changed range on an unnamed interface errors": {
+			old: []interface{}{
+				map[string]interface{}{"name": "", "alias_ip_range": []interface{}{}},
+			},
+			new: []interface{}{
+				map[string]interface{}{"name": "", "alias_ip_range": []interface{}{
+					map[string]interface{}{"ip_cidr_range": "10.0.3.0/24", "subnetwork_range_name": ""},
+				}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := networkInterfaceAliasIpRangeDiffs(tc.old, tc.new)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestUpdateNetworkInterfaceAliasIpRangesWithRetry_ExhaustsRetriesOn412
+// pins down the one behavior of the retry loop that doesn't depend on how
+// computeSharedOperationWait handles a successful operation: that a
+// persistently stale fingerprint (the API always returning 412) eventually
+// gives up instead of retrying forever, and that it refetches the
+// fingerprint before every attempt.
+func TestUpdateNetworkInterfaceAliasIpRangesWithRetry_ExhaustsRetriesOn412(t *testing.T) {
+	var getCount, patchCount int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			getCount++
+			fmt.Fprintf(w, `{"networkInterfaces": [{"name": "nic0", "fingerprint": "fp-%d"}]}`, getCount)
+		case "PATCH":
+			patchCount++
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `{"error": {"code": 412, "message": "stale fingerprint"}}`)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	svc, err := computeBeta.New(ts.Client())
+	if err != nil {
+		t.Fatalf("error creating fake compute service: %s", err)
+	}
+	svc.BasePath = ts.URL + "/"
+
+	config := &Config{clientComputeBeta: svc}
+
+	err = updateNetworkInterfaceAliasIpRangesWithRetry(config, "my-project", "us-central1-a", "my-instance", "nic0", nil)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries, got nil")
+	}
+	if getCount != 3 || patchCount != 3 {
+		t.Fatalf("expected 3 fingerprint refetches and 3 update attempts, got %d gets and %d patches", getCount, patchCount)
+	}
+}
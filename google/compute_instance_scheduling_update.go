@@ -0,0 +1,48 @@
+package google
+
+import (
+	"fmt"
+
+	computeBeta "google.golang.org/api/compute/v0.beta"
+)
+
+// updateScheduling applies a changed scheduling block to an instance.
+// Most scheduling fields (on_host_maintenance, automatic_restart) can be
+// changed on a running instance via Instances.SetScheduling, but
+// node_affinities can only be changed while the instance is stopped, so
+// stopRequired gates this between the two flows - mirroring the
+// stop/patch/start pattern updateGuestAccelerators already uses for
+// guest_accelerator.
+func updateScheduling(config *Config, project, zone, instance string, scheduling *computeBeta.Scheduling, stopRequired bool) error {
+	instancesService := config.clientComputeBeta.Instances
+
+	if !stopRequired {
+		op, err := instancesService.SetScheduling(project, zone, instance, scheduling).Do()
+		if err != nil {
+			return fmt.Errorf("Error updating scheduling on instance %q: %s", instance, err)
+		}
+		return computeSharedOperationWait(config.clientCompute, op, project, "updating scheduling")
+	}
+
+	stopOp, err := instancesService.Stop(project, zone, instance).Do()
+	if err != nil {
+		return fmt.Errorf("Error stopping instance %q to update node_affinities: %s", instance, err)
+	}
+	if err := computeSharedOperationWait(config.clientCompute, stopOp, project, "stopping instance"); err != nil {
+		return err
+	}
+
+	schedulingOp, err := instancesService.SetScheduling(project, zone, instance, scheduling).Do()
+	if err != nil {
+		return fmt.Errorf("Error updating scheduling on instance %q: %s", instance, err)
+	}
+	if err := computeSharedOperationWait(config.clientCompute, schedulingOp, project, "updating scheduling"); err != nil {
+		return err
+	}
+
+	startOp, err := instancesService.Start(project, zone, instance).Do()
+	if err != nil {
+		return fmt.Errorf("Error restarting instance %q after updating scheduling: %s", instance, err)
+	}
+	return computeSharedOperationWait(config.clientCompute, startOp, project, "starting instance")
+}
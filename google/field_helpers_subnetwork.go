@@ -0,0 +1,90 @@
+package google
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// SubnetworkFieldValue holds the parsed pieces of a subnetwork self link, or
+// a relative/partial form of one, mirroring NetworkFieldValue.
+type SubnetworkFieldValue struct {
+	Project string
+	Region  string
+	Name    string
+
+	resourceType string
+}
+
+// RelativeLink returns the subnetwork's partial URL, e.g.
+// "projects/my-project/regions/us-central1/subnetworks/my-subnetwork".
+func (s *SubnetworkFieldValue) RelativeLink() string {
+	if s.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", s.Project, s.Region, s.Name)
+}
+
+// subnetworkLinkRegex matches both a full self link (with a host/API-version
+// prefix before "projects/...") and the bare relative "projects/.../
+// regions/.../subnetworks/..." form, since the former is a superset of the
+// latter.
+var subnetworkLinkRegex = regexp.MustCompile(fmt.Sprintf(
+	"projects/(%s)/regions/(%s)/subnetworks/(%s)$", ProjectRegex, RegionRegex, NameRegex))
+var subnetworkRegionLinkRegex = regexp.MustCompile(fmt.Sprintf(
+	"^regions/(%s)/subnetworks/(%s)$", RegionRegex, NameRegex))
+var subnetworkNameRegex = regexp.MustCompile(fmt.Sprintf("^(%s)$", NameRegex))
+
+// ParseSubnetworkFieldValue parses a subnetwork field value in any of its
+// accepted forms - a full self link, a relative "projects/.../subnetworks/..."
+// link, a partial "regions/.../subnetworks/..." link, or a bare name - and
+// falls back to the provider's default project/region for whatever pieces
+// aren't present in the input, returning any error from looking those up.
+func ParseSubnetworkFieldValue(subnetwork string, d *schema.ResourceData, config *Config) (*SubnetworkFieldValue, error) {
+	if subnetwork == "" {
+		return &SubnetworkFieldValue{resourceType: "subnetworks"}, nil
+	}
+
+	if parts := subnetworkLinkRegex.FindStringSubmatch(subnetwork); parts != nil {
+		return &SubnetworkFieldValue{
+			Project:      parts[1],
+			Region:       parts[2],
+			Name:         parts[3],
+			resourceType: "subnetworks",
+		}, nil
+	}
+
+	project, region := "", ""
+	name := subnetwork
+
+	if parts := subnetworkRegionLinkRegex.FindStringSubmatch(subnetwork); parts != nil {
+		region = parts[1]
+		name = parts[2]
+	} else if !subnetworkNameRegex.MatchString(subnetwork) {
+		return nil, fmt.Errorf("invalid value for subnetwork: %s", subnetwork)
+	}
+
+	if project == "" {
+		var err error
+		project, err = getProject(d, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if region == "" {
+		var err error
+		region, err = getRegion(d, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &SubnetworkFieldValue{
+		Project:      project,
+		Region:       region,
+		Name:         name,
+		resourceType: "subnetworks",
+	}, nil
+}
@@ -0,0 +1,446 @@
+package google
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	computeBeta "google.golang.org/api/compute/v0.beta"
+)
+
+func resourceComputeInstance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceComputeInstanceCreate,
+		Read:   resourceComputeInstanceRead,
+		Update: resourceComputeInstanceUpdate,
+		Delete: resourceComputeInstanceDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"machine_type": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			// allow_stopping_for_update gates the disruptive stop/patch/start
+			// flow used to apply in-place changes (e.g. guest_accelerator)
+			// that the API can only accept while the instance is stopped.
+			"allow_stopping_for_update": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			// guest_accelerator is not ForceNew: changes are applied in place
+			// via the stop/patch/start flow in Update, gated by
+			// allow_stopping_for_update.
+			"guest_accelerator": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"count": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"scheduling": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"on_host_maintenance": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"preemptible": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+						},
+
+						"automatic_restart": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Computed: true,
+						},
+
+						// node_affinities targets the instance at a
+						// google_compute_node_group; changing it requires the
+						// instance to be stopped, so it's handled in Update
+						// alongside the rest of scheduling rather than ForceNew.
+						"node_affinities": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"key": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"operator": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"values": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+
+						"min_node_cpus": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			// network_interface is not itself ForceNew: alias_ip_range changes
+			// are handled in place by Update. Individual fields that do require
+			// replacement (network, subnetwork, access_config, ...) carry their
+			// own ForceNew below.
+			"network_interface": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"network": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+							ForceNew: true,
+						},
+
+						"subnetwork": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+							ForceNew: true,
+						},
+
+						"subnetwork_project": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+							ForceNew: true,
+						},
+
+						"network_ip": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+							ForceNew: true,
+						},
+
+						"address": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+							ForceNew: true,
+						},
+
+						"fingerprint": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						// alias_ip_range is intentionally not ForceNew: changes are
+						// applied in place by resourceComputeInstanceUpdate via
+						// updateNetworkInterfaceAliasIpRangesWithRetry instead of
+						// recreating the instance.
+						"alias_ip_range": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ip_cidr_range": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"subnetwork_range_name": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+
+						// access_config and ipv6_access_config are ForceNew for now:
+						// there's no Update path that calls the API for them yet, so
+						// letting a change through as an in-place diff would produce
+						// a clean apply that never actually touches the server and a
+						// perpetual diff afterward. Revisit once an update path
+						// (delete+add access config, matching upstream) lands.
+						"access_config": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"nat_ip": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Computed: true,
+									},
+									"assigned_nat_ip": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"network_tier": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Computed: true,
+									},
+									"public_ptr_domain_name": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+
+						"ipv6_access_config": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"network_tier": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Computed: true,
+									},
+									"public_ptr_domain_name": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceComputeInstanceCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+
+	project, err := getProject(d, config)
+	if err != nil {
+		return err
+	}
+	zone, err := getZone(d, config)
+	if err != nil {
+		return err
+	}
+
+	networkInterfaces, err := expandNetworkInterfaces(d, config)
+	if err != nil {
+		return err
+	}
+
+	scheduling, err := expandScheduling(d.Get("scheduling"))
+	if err != nil {
+		return err
+	}
+
+	instance := &computeBeta.Instance{
+		Name:              d.Get("name").(string),
+		MachineType:       d.Get("machine_type").(string),
+		NetworkInterfaces: networkInterfaces,
+		Scheduling:        scheduling,
+		GuestAccelerators: expandGuestAccelerators(d.Get("guest_accelerator").([]interface{})),
+	}
+
+	op, err := config.clientComputeBeta.Instances.Insert(project, zone, instance).Do()
+	if err != nil {
+		return fmt.Errorf("Error creating instance: %s", err)
+	}
+
+	d.SetId(instance.Name)
+
+	if err := computeSharedOperationWait(config.clientCompute, op, project, "creating instance"); err != nil {
+		return err
+	}
+
+	return resourceComputeInstanceRead(d, meta)
+}
+
+func resourceComputeInstanceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+
+	project, err := getProject(d, config)
+	if err != nil {
+		return err
+	}
+	zone, err := getZone(d, config)
+	if err != nil {
+		return err
+	}
+
+	instance, err := config.clientComputeBeta.Instances.Get(project, zone, d.Id()).Do()
+	if err != nil {
+		return handleNotFoundError(err, d, fmt.Sprintf("Instance %q", d.Get("name").(string)))
+	}
+
+	networkInterfaces, _, _, _, err := flattenNetworkInterfaces(d, config, instance.NetworkInterfaces)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("network_interface", networkInterfaces); err != nil {
+		return err
+	}
+
+	if instance.Scheduling != nil {
+		if err := d.Set("scheduling", flattenScheduling(instance.Scheduling)); err != nil {
+			return err
+		}
+	}
+
+	if err := d.Set("guest_accelerator", flattenGuestAccelerators(instance.GuestAccelerators)); err != nil {
+		return err
+	}
+
+	d.Set("name", instance.Name)
+	d.Set("machine_type", instance.MachineType)
+	d.Set("zone", zone)
+	d.Set("project", project)
+
+	return nil
+}
+
+func resourceComputeInstanceUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+
+	project, err := getProject(d, config)
+	if err != nil {
+		return err
+	}
+	zone, err := getZone(d, config)
+	if err != nil {
+		return err
+	}
+	instance := d.Id()
+
+	d.Partial(true)
+
+	if d.HasChange("network_interface") {
+		oldIfacesRaw, newIfacesRaw := d.GetChange("network_interface")
+		diffs, err := networkInterfaceAliasIpRangeDiffs(oldIfacesRaw.([]interface{}), newIfacesRaw.([]interface{}))
+		if err != nil {
+			return err
+		}
+
+		for _, diff := range diffs {
+			if err := updateNetworkInterfaceAliasIpRangesWithRetry(config, project, zone, instance, diff.Name, diff.Ranges); err != nil {
+				return fmt.Errorf("Error updating alias_ip_range on network_interface.%d: %s", diff.Index, err)
+			}
+		}
+
+		d.SetPartial("network_interface")
+	}
+
+	if d.HasChange("scheduling") {
+		scheduling, err := expandScheduling(d.Get("scheduling"))
+		if err != nil {
+			return fmt.Errorf("Error parsing scheduling: %s", err)
+		}
+
+		// node_affinities can only be changed while the instance is stopped,
+		// unlike the rest of the scheduling block.
+		stopRequired := d.HasChange("scheduling.0.node_affinities")
+		if stopRequired && !d.Get("allow_stopping_for_update").(bool) {
+			return fmt.Errorf("Changing the node_affinities on an instance requires stopping it. " +
+				"To acknowledge this, please set allow_stopping_for_update = true in your config.")
+		}
+
+		if err := updateScheduling(config, project, zone, instance, scheduling, stopRequired); err != nil {
+			return err
+		}
+
+		d.SetPartial("scheduling")
+	}
+
+	if d.HasChange("guest_accelerator") {
+		if !d.Get("allow_stopping_for_update").(bool) {
+			return fmt.Errorf("Changing the guest_accelerator on an instance requires stopping it. " +
+				"To acknowledge this, please set allow_stopping_for_update = true in your config.")
+		}
+
+		accelerators := expandGuestAccelerators(d.Get("guest_accelerator").([]interface{}))
+		if err := updateGuestAccelerators(config, project, zone, instance, accelerators); err != nil {
+			return fmt.Errorf("Error updating guest_accelerator: %s", err)
+		}
+
+		d.SetPartial("guest_accelerator")
+	}
+
+	d.Partial(false)
+
+	return resourceComputeInstanceRead(d, meta)
+}
+
+func resourceComputeInstanceDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+
+	project, err := getProject(d, config)
+	if err != nil {
+		return err
+	}
+	zone, err := getZone(d, config)
+	if err != nil {
+		return err
+	}
+
+	op, err := config.clientComputeBeta.Instances.Delete(project, zone, d.Id()).Do()
+	if err != nil {
+		return fmt.Errorf("Error deleting instance: %s", err)
+	}
+
+	if err := computeSharedOperationWait(config.clientCompute, op, project, "deleting instance"); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}